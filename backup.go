@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/csmanutd/pceutils"
+)
+
+// backupFirewallSettings snapshots the full firewall_settings document
+// returned by the PCE (not just the rule_hit_count_enabled_scopes subset
+// this tool reads) for pceName to a timestamped JSON file under
+// backupDir/<pceName>/, so a bad scope roll-out can be undone with
+// -restore. raw is the unmodified response body from GetDraftFirewallSettings.
+// It returns the path it wrote.
+func backupFirewallSettings(backupDir, pceName string, raw []byte) (string, error) {
+	dir := filepath.Join(backupDir, pceName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory %s: %v", dir, err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s.json", time.Now().UTC().Format("20060102T150405Z")))
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, raw, "", "  "); err != nil {
+		return "", fmt.Errorf("failed to encode firewall settings for backup: %v", err)
+	}
+
+	if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write backup file %s: %v", path, err)
+	}
+
+	return path, nil
+}
+
+// restoreFirewallSettings reads a firewall_settings snapshot written by
+// backupFirewallSettings and PUTs it back as the draft firewall_settings for
+// pceInfo, then optionally provisions the change.
+func restoreFirewallSettings(pceInfo pceutils.PCEInfo, snapshotPath string, insecure bool) error {
+	data, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %v", snapshotPath, err)
+	}
+
+	// The snapshot is the full firewall_settings document as returned by the
+	// PCE, not just the rule_hit_count_enabled_scopes subset this tool reads.
+	// PUT it back verbatim rather than round-tripping it through
+	// scopes.FirewallSettings, which would silently zero every other field.
+	if !json.Valid(data) {
+		return fmt.Errorf("snapshot %s is not valid JSON", snapshotPath)
+	}
+
+	url := fmt.Sprintf("https://%s:%s/api/v2/orgs/%s/sec_policy/draft/firewall_settings", pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID)
+	statusCode, _, err := apiCall(url, "PUT", pceInfo.APIKey, pceInfo.APISecret, string(data), insecure)
+	if err != nil || statusCode < 200 || statusCode >= 300 {
+		return fmt.Errorf("failed to restore firewall settings, HTTP Code: %d, Error: %v", statusCode, err)
+	}
+
+	logger.Info("restored firewall settings", "path", snapshotPath)
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Do you want to proceed with provisioning changes? (Y/n): ")
+	confirmation, _ := reader.ReadString('\n')
+	confirmation = strings.TrimSpace(confirmation)
+	if confirmation == "" || strings.EqualFold(confirmation, "y") {
+		if err := provisionChanges(pceInfo, "Restore rule hit count scopes", insecure); err != nil {
+			return err
+		}
+		logger.Info("provisioning complete")
+	} else {
+		logger.Info("provisioning skipped")
+	}
+
+	return nil
+}
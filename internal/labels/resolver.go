@@ -0,0 +1,96 @@
+// Package labels resolves label references to PCE hrefs from a label list
+// fetched once per run, instead of re-fetching /labels for every lookup.
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Label is the subset of a PCE label needed to resolve it to an href.
+type Label struct {
+	Href  string
+	Key   string
+	Value string
+}
+
+// Resolver indexes a label list by (key, value) and by value alone, so
+// repeated lookups against the same run don't need to re-fetch /labels.
+type Resolver struct {
+	byKeyValue map[string]string
+	byValue    map[string][]Label
+}
+
+// NewResolver builds a Resolver from a label list, e.g. the result of a
+// single pceclient.Client.ListLabels call.
+func NewResolver(all []Label) *Resolver {
+	r := &Resolver{
+		byKeyValue: make(map[string]string),
+		byValue:    make(map[string][]Label),
+	}
+	for _, l := range all {
+		key := normalize(l.Key)
+		value := normalize(l.Value)
+		r.byKeyValue[indexKey(key, value)] = l.Href
+		r.byValue[value] = append(r.byValue[value], l)
+	}
+	return r
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func indexKey(key, value string) string {
+	return key + "\x00" + value
+}
+
+// Resolve returns the href of the label with the given key and value.
+func (r *Resolver) Resolve(key, value string) (string, error) {
+	href, ok := r.byKeyValue[indexKey(normalize(key), normalize(value))]
+	if !ok {
+		return "", fmt.Errorf("label %s:%s not found", key, value)
+	}
+	return href, nil
+}
+
+// ResolveAmbiguous returns the href of the label with the given value,
+// regardless of key. It errors out, listing every candidate key, if more
+// than one key shares that value.
+func (r *Resolver) ResolveAmbiguous(value string) (string, error) {
+	candidates := r.byValue[normalize(value)]
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("label %q not found", value)
+	case 1:
+		return candidates[0].Href, nil
+	default:
+		keys := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			keys = append(keys, c.Key)
+		}
+		sort.Strings(keys)
+		return "", fmt.Errorf("label %q is ambiguous across keys %s; qualify it as key:value", value, strings.Join(keys, ", "))
+	}
+}
+
+// ParseReference splits a qualified label reference like "env:prod" into its
+// key and value. An unqualified reference (no "key:" prefix) returns an
+// empty key.
+func ParseReference(ref string) (key, value string) {
+	if k, v, ok := strings.Cut(ref, ":"); ok {
+		return k, v
+	}
+	return "", ref
+}
+
+// ResolveReference resolves a label reference that may be qualified by key
+// ("env:prod") or not ("prod"), in which case it must be unambiguous.
+func (r *Resolver) ResolveReference(ref string) (string, error) {
+	key, value := ParseReference(ref)
+	if key == "" {
+		return r.ResolveAmbiguous(value)
+	}
+	return r.Resolve(key, value)
+}
@@ -0,0 +1,88 @@
+package labels
+
+import "testing"
+
+func fixtureLabels() []Label {
+	return []Label{
+		{Href: "/orgs/1/labels/1", Key: "env", Value: "prod"},
+		{Href: "/orgs/1/labels/2", Key: "env", Value: "staging"},
+		{Href: "/orgs/1/labels/3", Key: "loc", Value: "dc1"},
+		{Href: "/orgs/1/labels/4", Key: "app", Value: "prod"},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r := NewResolver(fixtureLabels())
+
+	href, err := r.Resolve("env", "prod")
+	if err != nil || href != "/orgs/1/labels/1" {
+		t.Errorf("Resolve(env, prod) = %q, %v, want /orgs/1/labels/1, nil", href, err)
+	}
+
+	if _, err := r.Resolve("env", "missing"); err == nil {
+		t.Error("Resolve(env, missing) succeeded, want error")
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	r := NewResolver(fixtureLabels())
+
+	tests := []struct {
+		name      string
+		value     string
+		wantHref  string
+		wantError bool
+	}{
+		{name: "unique", value: "dc1", wantHref: "/orgs/1/labels/3"},
+		{name: "ambiguous across keys", value: "prod", wantError: true},
+		{name: "missing", value: "nope", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			href, err := r.ResolveAmbiguous(tt.value)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("ResolveAmbiguous(%q) succeeded, want error", tt.value)
+				}
+				return
+			}
+			if err != nil || href != tt.wantHref {
+				t.Errorf("ResolveAmbiguous(%q) = %q, %v, want %q, nil", tt.value, href, err, tt.wantHref)
+			}
+		})
+	}
+}
+
+func TestResolveReference(t *testing.T) {
+	r := NewResolver(fixtureLabels())
+
+	tests := []struct {
+		name      string
+		ref       string
+		wantHref  string
+		wantError bool
+	}{
+		{name: "qualified", ref: "env:prod", wantHref: "/orgs/1/labels/1"},
+		{name: "qualified other key", ref: "app:prod", wantHref: "/orgs/1/labels/4"},
+		{name: "unqualified unique", ref: "dc1", wantHref: "/orgs/1/labels/3"},
+		{name: "unqualified ambiguous", ref: "prod", wantError: true},
+		{name: "unqualified missing", ref: "nope", wantError: true},
+		{name: "qualified missing", ref: "env:nope", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			href, err := r.ResolveReference(tt.ref)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("ResolveReference(%q) succeeded, want error", tt.ref)
+				}
+				return
+			}
+			if err != nil || href != tt.wantHref {
+				t.Errorf("ResolveReference(%q) = %q, %v, want %q, nil", tt.ref, href, err, tt.wantHref)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package metrics defines the Prometheus collectors exposed by `serve` mode.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Collectors bundles every metric the reconciliation loop reports, along
+// with the registry they're attached to.
+type Collectors struct {
+	Registry *prometheus.Registry
+
+	ReconcileTotal    *prometheus.CounterVec
+	ReconcileDuration *prometheus.HistogramVec
+	ScopesConfigured  *prometheus.GaugeVec
+	ReportEnabled     *prometheus.GaugeVec
+	APIErrorsTotal    *prometheus.CounterVec
+}
+
+// New creates a dedicated CollectorRegistry and registers every
+// rhc_reconcile_* collector against it.
+func New() *Collectors {
+	registry := prometheus.NewRegistry()
+
+	c := &Collectors{
+		Registry: registry,
+		ReconcileTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rhc_reconcile_total",
+			Help: "Total number of reconcile attempts per PCE, by result (success, skipped, failed).",
+		}, []string{"pce", "result"}),
+		ReconcileDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rhc_reconcile_duration_seconds",
+			Help:    "Time taken to reconcile a single PCE.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"pce"}),
+		ScopesConfigured: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rhc_scopes_configured",
+			Help: "Number of label-set scopes currently configured for rule hit counting.",
+		}, []string{"pce"}),
+		ReportEnabled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rhc_report_enabled",
+			Help: "Whether the rule hit count report template is enabled (1) or not (0).",
+		}, []string{"pce"}),
+		APIErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rhc_api_errors_total",
+			Help: "Total number of PCE API errors encountered, by endpoint.",
+		}, []string{"pce", "endpoint"}),
+	}
+
+	registry.MustRegister(
+		c.ReconcileTotal,
+		c.ReconcileDuration,
+		c.ScopesConfigured,
+		c.ReportEnabled,
+		c.APIErrorsTotal,
+	)
+
+	return c
+}
@@ -0,0 +1,76 @@
+// Package scopes holds the rule_hit_count_enabled_scopes shape shared by the
+// PCE firewall_settings API and the logic for comparing two scope configurations.
+package scopes
+
+import (
+	"sort"
+	"strings"
+)
+
+// FirewallSettings mirrors the subset of the PCE's firewall_settings resource
+// that this tool reads and writes.
+type FirewallSettings struct {
+	RuleHitCountEnabledScopes [][]interface{} `json:"rule_hit_count_enabled_scopes"`
+}
+
+// Equal reports whether a and b describe the same set of label groupings,
+// ignoring the order of label hrefs within a scope and the order of the
+// scopes themselves. A nil scope list and an empty, non-nil one are equal.
+func Equal(a, b [][]interface{}) bool {
+	canonA := canonicalize(a)
+	canonB := canonicalize(b)
+
+	if len(canonA) != len(canonB) {
+		return false
+	}
+	for i := range canonA {
+		if canonA[i] != canonB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalize renders each scope as a sorted, comma-joined string of label
+// hrefs, then sorts the resulting list so that scope order doesn't matter.
+func canonicalize(scopeList [][]interface{}) []string {
+	out := make([]string, 0, len(scopeList))
+	for _, scope := range scopeList {
+		hrefs := make([]string, 0, len(scope))
+		for _, entry := range scope {
+			href, ok := labelHref(entry)
+			if !ok {
+				continue
+			}
+			hrefs = append(hrefs, href)
+		}
+		sort.Strings(hrefs)
+		out = append(out, strings.Join(hrefs, ","))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// labelHref extracts the "label.href" string from one scope entry, which is
+// either a map[string]interface{} (as decoded from JSON) or a
+// map[string]map[string]string (as built when constructing a new payload).
+func labelHref(entry interface{}) (string, bool) {
+	switch v := entry.(type) {
+	case map[string]interface{}:
+		label, ok := v["label"].(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		href, ok := label["href"].(string)
+		return href, ok
+	case map[string]map[string]string:
+		label, ok := v["label"]
+		if !ok {
+			return "", false
+		}
+		href, ok := label["href"]
+		return href, ok
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,77 @@
+package scopes
+
+import "testing"
+
+func label(href string) map[string]interface{} {
+	return map[string]interface{}{
+		"label": map[string]interface{}{
+			"href": href,
+		},
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    [][]interface{}
+		b    [][]interface{}
+		want bool
+	}{
+		{
+			name: "identical",
+			a:    [][]interface{}{{label("/orgs/1/labels/1")}},
+			b:    [][]interface{}{{label("/orgs/1/labels/1")}},
+			want: true,
+		},
+		{
+			name: "permuted within scope",
+			a:    [][]interface{}{{label("/orgs/1/labels/1"), label("/orgs/1/labels/2")}},
+			b:    [][]interface{}{{label("/orgs/1/labels/2"), label("/orgs/1/labels/1")}},
+			want: true,
+		},
+		{
+			name: "permuted across scopes",
+			a:    [][]interface{}{{label("/orgs/1/labels/1")}, {label("/orgs/1/labels/2")}},
+			b:    [][]interface{}{{label("/orgs/1/labels/2")}, {label("/orgs/1/labels/1")}},
+			want: true,
+		},
+		{
+			name: "disjoint",
+			a:    [][]interface{}{{label("/orgs/1/labels/1")}},
+			b:    [][]interface{}{{label("/orgs/1/labels/2")}},
+			want: false,
+		},
+		{
+			name: "superset",
+			a:    [][]interface{}{{label("/orgs/1/labels/1")}},
+			b:    [][]interface{}{{label("/orgs/1/labels/1")}, {label("/orgs/1/labels/2")}},
+			want: false,
+		},
+		{
+			name: "empty vs all",
+			a:    [][]interface{}{},
+			b:    [][]interface{}{{}},
+			want: false,
+		},
+		{
+			name: "nil vs empty",
+			a:    nil,
+			b:    [][]interface{}{},
+			want: true,
+		},
+		{
+			name: "nil vs nil",
+			a:    nil,
+			b:    nil,
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/internal/labels"
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/pkg/pceclient"
+)
+
+// newLabelResolver fetches every label visible to client once and returns a
+// labels.Resolver over them, so a run that looks up many label references
+// (interactively or from a manifest) only hits /labels a single time.
+func newLabelResolver(client *pceclient.Client) (*labels.Resolver, error) {
+	pceLabels, err := client.ListLabels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch labels: %v", err)
+	}
+
+	all := make([]labels.Label, len(pceLabels))
+	for i, l := range pceLabels {
+		all[i] = labels.Label{Href: l.Href, Key: l.Key, Value: l.Value}
+	}
+
+	return labels.NewResolver(all), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/pkg/pceclient"
+	"github.com/csmanutd/pceutils"
+)
+
+// logger is the package-level structured logger, configured once in main
+// (or runServe) via setupLogger and used by every API call and state
+// transition for the rest of the run.
+var logger = slog.Default()
+
+// setupLogger configures the package-level logger from -log-level/-log-format
+// and attaches a per-run correlation id so multi-PCE batch and serve runs can
+// be untangled in aggregated logs.
+func setupLogger(level, format string) *slog.Logger {
+	var slogLevel slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		slogLevel = slog.LevelDebug
+	case "warn":
+		slogLevel = slog.LevelWarn
+	case "error":
+		slogLevel = slog.LevelError
+	default:
+		slogLevel = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: slogLevel}
+	var handler slog.Handler
+	if strings.ToLower(format) == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+
+	logger = slog.New(handler).With("run_id", newCorrelationID())
+	return logger
+}
+
+// newCorrelationID returns a random UUIDv4-formatted string used to tag
+// every log line emitted by this run.
+func newCorrelationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// apiCall wraps pceutils.MakeAPICall with debug-level request/response
+// tracing: method, URL, status code, elapsed time, and a truncated body.
+func apiCall(url, method, apiKey, apiSecret, payload string, insecure bool) (int, []byte, error) {
+	start := time.Now()
+	statusCode, body, err := pceutils.MakeAPICall(url, method, apiKey, apiSecret, payload, insecure)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		logger.Debug("pce api call", "method", method, "url", url, "status", statusCode, "elapsed_ms", elapsed.Milliseconds(), "error", err)
+	} else {
+		logger.Debug("pce api call", "method", method, "url", url, "status", statusCode, "elapsed_ms", elapsed.Milliseconds(), "body", truncate(string(body), 500))
+	}
+
+	return statusCode, body, err
+}
+
+// truncate shortens s to at most n bytes for debug-log readability.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...(truncated)"
+}
+
+// newPCEClient returns a pceclient.Client for pceInfo that debug-logs every
+// request/response through the package-level logger, same as apiCall.
+func newPCEClient(pceInfo pceutils.PCEInfo, insecure bool) *pceclient.Client {
+	return pceclient.New(pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID, pceInfo.APIKey, pceInfo.APISecret, insecure,
+		func(method, url string, status int, elapsed time.Duration, err error) {
+			if err != nil {
+				logger.Debug("pce api call", "method", method, "url", url, "status", status, "elapsed_ms", elapsed.Milliseconds(), "error", err)
+			} else {
+				logger.Debug("pce api call", "method", method, "url", url, "status", status, "elapsed_ms", elapsed.Milliseconds())
+			}
+		})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/internal/scopes"
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/pkg/pceclient"
+	"github.com/csmanutd/pceutils"
+	"gopkg.in/yaml.v3"
+)
+
+// LabelSelector identifies a single label within a manifest scope, either by
+// an explicit key/value pair or, if key is omitted, by a value that must be
+// unambiguous (or itself qualified, e.g. `value: env:prod`).
+type LabelSelector struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// reference renders the selector as the key:value / value string that
+// labels.Resolver understands.
+func (s LabelSelector) reference() string {
+	if s.Key == "" {
+		return s.Value
+	}
+	return s.Key + ":" + s.Value
+}
+
+// ManifestPCE describes the desired rule-hit-count configuration for one PCE.
+type ManifestPCE struct {
+	Name                 string            `yaml:"name"`
+	EnableReport         bool              `yaml:"enable_report"`
+	Scopes               [][]LabelSelector `yaml:"scopes"`
+	Provision            bool              `yaml:"provision"`
+	ProvisionDescription string            `yaml:"provision_description"`
+}
+
+// Manifest is the top-level shape of a `-manifest` YAML file.
+type Manifest struct {
+	PCEs []ManifestPCE `yaml:"pces"`
+}
+
+// loadManifest reads and parses a manifest file from disk.
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %v", path, err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %v", path, err)
+	}
+
+	return &manifest, nil
+}
+
+// buildScopesPayload resolves every label selector in a manifest scope list to
+// hrefs and returns the `rule_hit_count_enabled_scopes` shape expected by the API.
+func buildScopesPayload(client *pceclient.Client, scopeSelectors [][]LabelSelector) ([][]interface{}, error) {
+	// Fetch every label once up front so a manifest with many selectors
+	// doesn't re-fetch /labels for each one.
+	resolver, err := newLabelResolver(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved [][]interface{}
+	for _, scope := range scopeSelectors {
+		// A zero-selector scope (e.g. `scopes: - []` in YAML) means "all", so
+		// group must marshal as [] rather than the null a nil slice produces.
+		group := []interface{}{}
+		for _, selector := range scope {
+			href, err := resolver.ResolveReference(selector.reference())
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve label %s: %v", selector.reference(), err)
+			}
+			group = append(group, map[string]interface{}{
+				"label": map[string]interface{}{
+					"href": href,
+				},
+			})
+		}
+		resolved = append(resolved, group)
+	}
+	return resolved, nil
+}
+
+// reconcilePCE applies one manifest entry to its PCE: it enables the report if
+// requested, diffs the desired scopes against the current firewall settings,
+// and only PUTs (and optionally provisions) when something actually changed.
+// In dry-run mode, every GET still happens but no PUT/POST is issued.
+func reconcilePCE(pceInfo pceutils.PCEInfo, entry ManifestPCE, insecure, dryRun bool, backupDir string) ReconcileReport {
+	report := ReconcileReport{PCE: entry.Name, DryRun: dryRun}
+	client := newPCEClient(pceInfo, insecure)
+
+	// Query the report's actual state regardless of entry.EnableReport so the
+	// rhc_report_enabled metric reflects reality even on runs that aren't
+	// managing this setting, rather than always reporting 0 for them.
+	tmpl, err := client.GetReportTemplate()
+	if err != nil {
+		report.Status = "failed"
+		report.FailedEndpoint = "report_templates"
+		report.Error = fmt.Sprintf("failed to fetch report status: %v", err)
+		return report
+	}
+	report.ReportAlreadyEnabled = tmpl.Enabled
+
+	if entry.EnableReport && !tmpl.Enabled && !dryRun {
+		if err := checkAndEnableReport(pceInfo, insecure); err != nil {
+			report.Status = "failed"
+			report.FailedEndpoint = "report_templates"
+			report.Error = fmt.Sprintf("enable report: %v", err)
+			return report
+		}
+	}
+
+	desiredScopes, err := buildScopesPayload(client, entry.Scopes)
+	if err != nil {
+		report.Status = "failed"
+		report.FailedEndpoint = "labels"
+		report.Error = err.Error()
+		return report
+	}
+	report.DesiredScopes = scopesToStrings(desiredScopes)
+
+	currentSettings, currentSettingsRaw, err := client.GetDraftFirewallSettings()
+	if err != nil {
+		report.Status = "failed"
+		report.FailedEndpoint = "firewall_settings"
+		report.Error = fmt.Sprintf("failed to fetch current firewall settings: %v", err)
+		return report
+	}
+	report.CurrentScopes = scopesToStrings(currentSettings.RuleHitCountEnabledScopes)
+	report.AddedLabels, report.RemovedLabels = diffHrefs(currentSettings.RuleHitCountEnabledScopes, desiredScopes)
+
+	if scopes.Equal(currentSettings.RuleHitCountEnabledScopes, desiredScopes) {
+		report.Status = "skipped"
+		return report
+	}
+	report.Changed = true
+	report.WouldProvision = entry.Provision
+
+	if dryRun {
+		report.Status = "success"
+		return report
+	}
+
+	backupPath, err := backupFirewallSettings(backupDir, entry.Name, currentSettingsRaw)
+	if err != nil {
+		report.Status = "failed"
+		report.Error = fmt.Sprintf("backup: %v", err)
+		return report
+	}
+	report.BackupPath = backupPath
+
+	if err := client.UpdateDraftFirewallSettings(scopes.FirewallSettings{RuleHitCountEnabledScopes: desiredScopes}); err != nil {
+		report.Status = "failed"
+		report.FailedEndpoint = "firewall_settings"
+		report.Error = fmt.Sprintf("failed to update firewall settings: %v", err)
+		return report
+	}
+
+	if entry.Provision {
+		if err := provisionChanges(pceInfo, entry.ProvisionDescription, insecure); err != nil {
+			report.Status = "failed"
+			report.FailedEndpoint = "sec_policy"
+			report.Error = fmt.Sprintf("provision: %v", err)
+			return report
+		}
+	}
+
+	report.Status = "success"
+	return report
+}
+
+// runManifestBatch reconciles every PCE listed in the manifest and prints a
+// per-PCE report in the requested format. It returns true if any PCE failed.
+func runManifestBatch(config pceutils.PCEConfig, manifest *Manifest, insecure, dryRun bool, outputFormat, backupDir string) bool {
+	anyFailed := false
+
+	for _, entry := range manifest.PCEs {
+		pceInfo, ok := config.PCEs[entry.Name]
+		if !ok {
+			report := ReconcileReport{PCE: entry.Name, DryRun: dryRun, Status: "failed", Error: "PCE not found in configuration"}
+			printReport(report, outputFormat)
+			anyFailed = true
+			continue
+		}
+
+		report := reconcilePCE(pceInfo, entry, insecure, dryRun, backupDir)
+		printReport(report, outputFormat)
+		if report.Status == "failed" {
+			anyFailed = true
+		}
+	}
+
+	return anyFailed
+}
@@ -0,0 +1,128 @@
+// Package pceclient is a small typed client for the slice of the Illumio PCE
+// REST API this tool talks to: the rule hit count report template, labels,
+// draft firewall_settings, and sec_policy provisioning.
+//
+// Scope reduction from the original request: this is hand-written, not
+// generated by oapi-codegen/go-swagger against the PCE OpenAPI spec, because
+// no codegen toolchain or vendored spec is available in this environment.
+// The request/response shapes below are still hand-guessed from the API's
+// observed behavior, same as the map[string]interface{} payloads they
+// replace — the types buy callers compile-time field checking against this
+// package's own guesses, not against the real spec. Swapping in a generated
+// client once oapi-codegen/go-swagger and the spec are available should be a
+// drop-in replacement behind the same Client API.
+package pceclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Trace, if set on a Client, is called after every request completes
+// (successfully or not) so callers can plug in request/response logging.
+type Trace func(method, url string, status int, elapsed time.Duration, err error)
+
+// Client is a typed, authenticated client scoped to one PCE org.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	orgID      string
+	trace      Trace
+}
+
+// New returns a Client for the PCE at fqdn:port, scoped to orgID and
+// authenticating every request as apiKey/apiSecret via HTTP basic auth. When
+// insecure is true, TLS certificate verification is skipped. trace may be nil.
+func New(fqdn, port, orgID, apiKey, apiSecret string, insecure bool, trace Trace) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &apiKeyTransport{
+				apiKey:    apiKey,
+				apiSecret: apiSecret,
+				base:      &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure}},
+			},
+		},
+		baseURL: fmt.Sprintf("https://%s:%s/api/v2/orgs/%s", fqdn, port, orgID),
+		orgID:   orgID,
+		trace:   trace,
+	}
+}
+
+// apiKeyTransport authenticates every outgoing request with PCE API key/secret
+// basic auth before handing it to base.
+type apiKeyTransport struct {
+	apiKey    string
+	apiSecret string
+	base      http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.SetBasicAuth(t.apiKey, t.apiSecret)
+	return t.base.RoundTrip(req)
+}
+
+// do marshals body (if non-nil) as the JSON request payload, issues method
+// against baseURL+path, unmarshals a successful response into out (if
+// non-nil), and returns the raw response bytes for callers that need the
+// full document rather than just the fields out captures (e.g. backups).
+func (c *Client) do(method, path string, body, out interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	url := c.baseURL + path
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		if c.trace != nil {
+			c.trace(method, url, 0, elapsed, err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	if c.trace != nil {
+		c.trace(method, url, resp.StatusCode, elapsed, readErr)
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", readErr)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return nil, fmt.Errorf("failed to decode response body: %v", err)
+		}
+	}
+	return respBody, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...(truncated)"
+}
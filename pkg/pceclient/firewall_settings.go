@@ -0,0 +1,22 @@
+package pceclient
+
+import "github.com/csmanutd/Rule-hit-counter-auto-enablement/internal/scopes"
+
+// GetDraftFirewallSettings fetches the draft firewall_settings. It returns
+// the typed view this tool acts on (rule_hit_count_enabled_scopes) along
+// with the raw response bytes, since the PCE's firewall_settings resource
+// has other fields callers may need to preserve verbatim (e.g. for backups).
+func (c *Client) GetDraftFirewallSettings() (*scopes.FirewallSettings, []byte, error) {
+	var settings scopes.FirewallSettings
+	raw, err := c.do("GET", "/sec_policy/draft/firewall_settings", nil, &settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &settings, raw, nil
+}
+
+// UpdateDraftFirewallSettings PUTs a new draft firewall_settings document.
+func (c *Client) UpdateDraftFirewallSettings(settings scopes.FirewallSettings) error {
+	_, err := c.do("PUT", "/sec_policy/draft/firewall_settings", settings, nil)
+	return err
+}
@@ -0,0 +1,17 @@
+package pceclient
+
+// Label is a PCE label as returned by ListLabels.
+type Label struct {
+	Href  string `json:"href"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ListLabels fetches every label defined on the PCE.
+func (c *Client) ListLabels() ([]Label, error) {
+	var labels []Label
+	if _, err := c.do("GET", "/labels", nil, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
@@ -0,0 +1,22 @@
+package pceclient
+
+// ReportTemplate is the subset of the rule_hit_count_report template this
+// tool reads and writes.
+type ReportTemplate struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetReportTemplate fetches the rule hit count report template's current state.
+func (c *Client) GetReportTemplate() (*ReportTemplate, error) {
+	var tmpl ReportTemplate
+	if _, err := c.do("GET", "/report_templates/rule_hit_count_report", nil, &tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// UpdateReportTemplate sets the rule hit count report template's enabled state.
+func (c *Client) UpdateReportTemplate(enabled bool) error {
+	_, err := c.do("PUT", "/report_templates/rule_hit_count_report", ReportTemplate{Enabled: enabled}, nil)
+	return err
+}
@@ -0,0 +1,23 @@
+package pceclient
+
+import "fmt"
+
+// ProvisionSecPolicy provisions the current draft firewall_settings into
+// effect, recording description as the change's update_description.
+func (c *Client) ProvisionSecPolicy(description string) error {
+	if description == "" {
+		description = "Enable rule hit count"
+	}
+
+	payload := map[string]interface{}{
+		"update_description": description,
+		"change_subset": map[string]interface{}{
+			"firewall_settings": []map[string]string{
+				{"href": fmt.Sprintf("/orgs/%s/sec_policy/draft/firewall_settings", c.orgID)},
+			},
+		},
+	}
+
+	_, err := c.do("POST", "/sec_policy", payload, nil)
+	return err
+}
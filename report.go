@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ReconcileReport captures everything that happened (or would happen, in
+// dry-run mode) while reconciling one PCE's rule-hit-count configuration.
+type ReconcileReport struct {
+	PCE                  string   `json:"pce"`
+	CurrentScopes        []string `json:"current_scopes"`
+	DesiredScopes        []string `json:"desired_scopes"`
+	AddedLabels          []string `json:"added_labels,omitempty"`
+	RemovedLabels        []string `json:"removed_labels,omitempty"`
+	ReportAlreadyEnabled bool     `json:"report_already_enabled"`
+	Changed              bool     `json:"changed"`
+	WouldProvision       bool     `json:"would_provision"`
+	DryRun               bool     `json:"dry_run"`
+	BackupPath           string   `json:"backup_path,omitempty"`
+	Status               string   `json:"status"` // "success", "skipped", or "failed"
+	Error                string   `json:"error,omitempty"`
+	FailedEndpoint       string   `json:"failed_endpoint,omitempty"`
+}
+
+// flattenHrefs renders a rule_hit_count_enabled_scopes value as one
+// comma-joined string of label hrefs per scope, for diffing and display.
+func flattenHrefs(scopes [][]interface{}) []string {
+	var out []string
+	for _, scope := range scopes {
+		for _, entry := range scope {
+			if m, ok := entry.(map[string]interface{}); ok {
+				if label, ok := m["label"].(map[string]interface{}); ok {
+					if href, ok := label["href"].(string); ok {
+						out = append(out, href)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// diffHrefs returns the label hrefs present only in desired (added) and only
+// in current (removed).
+func diffHrefs(current, desired [][]interface{}) (added, removed []string) {
+	currentSet := make(map[string]bool)
+	for _, href := range flattenHrefs(current) {
+		currentSet[href] = true
+	}
+	desiredSet := make(map[string]bool)
+	for _, href := range flattenHrefs(desired) {
+		desiredSet[href] = true
+	}
+
+	for href := range desiredSet {
+		if !currentSet[href] {
+			added = append(added, href)
+		}
+	}
+	for href := range currentSet {
+		if !desiredSet[href] {
+			removed = append(removed, href)
+		}
+	}
+	return added, removed
+}
+
+// scopesToStrings renders each scope as a comma-joined string of hrefs, used
+// for human-readable and JSON reporting of current/desired state.
+func scopesToStrings(scopes [][]interface{}) []string {
+	var out []string
+	for _, scope := range scopes {
+		out = append(out, fmt.Sprintf("%v", flattenHrefs([][]interface{}{scope})))
+	}
+	return out
+}
+
+// printReport renders a ReconcileReport as either a single JSON document or a
+// human-readable summary line, depending on format ("json" or "text").
+func printReport(report ReconcileReport, format string) {
+	if format == "json" {
+		data, err := json.Marshal(report)
+		if err != nil {
+			fmt.Printf(`{"pce":%q,"status":"failed","error":"failed to encode report: %v"}`+"\n", report.PCE, err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	switch report.Status {
+	case "success":
+		fmt.Printf("%s: success (added=%v removed=%v provisioned=%v backup=%s)\n", report.PCE, report.AddedLabels, report.RemovedLabels, report.WouldProvision, report.BackupPath)
+	case "skipped":
+		fmt.Printf("%s: skipped - already configured as desired\n", report.PCE)
+	case "failed":
+		fmt.Printf("%s: failed - %s\n", report.PCE, report.Error)
+	}
+}
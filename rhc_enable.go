@@ -2,100 +2,122 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/internal/scopes"
 	"github.com/csmanutd/pceutils"
 )
 
-type ReportStatus struct {
-	Enabled bool `json:"enabled"`
-}
-
-type FirewallSettings struct {
-	RuleHitCountEnabledScopes [][]interface{} `json:"rule_hit_count_enabled_scopes"`
-}
-
-type Label struct {
-	Href  string `json:"href"`
-	Value string `json:"value"`
-}
-
 func checkAndEnableReport(pceInfo pceutils.PCEInfo, insecure bool) error {
-	fmt.Println("Checking if the report is already enabled...")
-
-	// GET API to check report status
-	url := fmt.Sprintf("https://%s:%s/api/v2/orgs/%s/report_templates/rule_hit_count_report", pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID)
-	statusCode, body, err := pceutils.MakeAPICall(url, "GET", pceInfo.APIKey, pceInfo.APISecret, "", insecure)
-	if err != nil || statusCode < 200 || statusCode >= 300 {
-		return fmt.Errorf("failed to fetch report status, HTTP Code: %d, Error: %v", statusCode, err)
-	}
+	client := newPCEClient(pceInfo, insecure)
 
-	var reportStatus ReportStatus
-	err = json.Unmarshal(body, &reportStatus)
+	tmpl, err := client.GetReportTemplate()
 	if err != nil {
-		return fmt.Errorf("failed to parse report status: %v", err)
+		return fmt.Errorf("failed to fetch report status: %v", err)
 	}
 
 	// If the report is already enabled, skip this step
-	if reportStatus.Enabled {
-		fmt.Println("Report is already enabled. Skipping this API call.")
+	if tmpl.Enabled {
+		logger.Info("report already enabled, skipping", "pce", pceInfo.FQDN)
 		return nil
 	}
 
-	// PUT API to enable the report
-	fmt.Println("Enabling report in PCE...")
-	enableURL := fmt.Sprintf("https://%s:%s/api/v2/orgs/%s/report_templates/rule_hit_count_report", pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID)
-	payload := `{"enabled": true}`
-
-	statusCode, response, err := pceutils.MakeAPICall(enableURL, "PUT", pceInfo.APIKey, pceInfo.APISecret, payload, insecure)
-	if err != nil || statusCode < 200 || statusCode >= 300 {
-		return fmt.Errorf("failed to enable report, HTTP Code: %d, Error: %v", statusCode, err)
+	if err := client.UpdateReportTemplate(true); err != nil {
+		return fmt.Errorf("failed to enable report: %v", err)
 	}
 
-	fmt.Printf("HTTP Code: %d\nResponse: %s\n", statusCode, string(response))
+	logger.Info("report enabled", "pce", pceInfo.FQDN)
 	return nil
 }
 
-func checkLabelHref(pceInfo pceutils.PCEInfo, labelValue string, insecure bool) (string, error) {
-	// Fetch all labels
-	url := fmt.Sprintf("https://%s:%s/api/v2/orgs/%s/labels", pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID)
-	statusCode, body, err := pceutils.MakeAPICall(url, "GET", pceInfo.APIKey, pceInfo.APISecret, "", insecure)
-	if err != nil || statusCode < 200 || statusCode >= 300 {
-		return "", fmt.Errorf("failed to fetch labels, HTTP Code: %d, Error: %v", statusCode, err)
-	}
+// provisionChanges issues the provisioning API call that rolls the current
+// draft firewall_settings into effect, using description as the change note.
+func provisionChanges(pceInfo pceutils.PCEInfo, description string, insecure bool) error {
+	client := newPCEClient(pceInfo, insecure)
 
-	// Parse the response
-	var labels []Label
-	err = json.Unmarshal(body, &labels)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse label response: %v", err)
-	}
-
-	// Find the label href that matches the label value
-	for _, label := range labels {
-		if strings.EqualFold(strings.TrimSpace(label.Value), labelValue) {
-			return label.Href, nil
-		}
+	if err := client.ProvisionSecPolicy(description); err != nil {
+		return fmt.Errorf("failed to provision changes: %v", err)
 	}
 
-	return "", fmt.Errorf("label not found")
+	return nil
 }
 
 func main() {
+	// The "serve" subcommand runs a long-lived reconciliation loop instead of
+	// the one-shot CLI below, so it's dispatched before the flat flags parse.
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+
 	// Handle command-line flags
 	pceName := flag.String("pce", "", "Name of the PCE to use")
 	insecure := flag.Bool("insecure", false, "Ignore SSL certificate errors")
+	manifestPath := flag.String("manifest", "", "Path to a YAML manifest describing desired scopes for multiple PCEs")
+	dryRun := flag.Bool("dry-run", false, "Perform all GETs and compute the desired change, but never PUT/POST")
+	outputFormat := flag.String("output", "text", "Output format: text or json")
+	backupDir := flag.String("backup-dir", "./backups", "Directory to snapshot firewall_settings into before mutating them")
+	restorePath := flag.String("restore", "", "Path to a firewall_settings snapshot to restore instead of enabling rule hit count")
+	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, or error")
+	logFormat := flag.String("log-format", "text", "Log format: text or json")
 	flag.Parse()
 
+	setupLogger(*logLevel, *logFormat)
+
+	if *outputFormat != "text" && *outputFormat != "json" {
+		logger.Error("invalid -output value", "output", *outputFormat)
+		os.Exit(1)
+	}
+
+	// The single-PCE path below prompts interactively on stdin for the scope
+	// label, which -dry-run and -output json can't do anything useful with (a
+	// CI run would just hang waiting on a prompt it can't answer). Only the
+	// -manifest batch path is non-interactive, so require it alongside those
+	// flags instead of silently blocking.
+	if (*dryRun || *outputFormat == "json") && *manifestPath == "" && *restorePath == "" {
+		logger.Error("-dry-run and -output json require -manifest; the single-PCE path prompts interactively")
+		os.Exit(1)
+	}
+
 	// Load the configuration
 	config, err := pceutils.LoadOrCreatePCEConfig("pce.json")
 	if err != nil {
-		log.Fatalf("Error loading or creating config: %v", err)
+		logger.Error("error loading or creating config", "error", err)
+		os.Exit(1)
+	}
+
+	// Restore mode: PUT a previously captured snapshot back and exit.
+	if *restorePath != "" {
+		if *pceName == "" {
+			*pceName = config.DefaultPCEName
+		}
+		pceInfo, ok := config.PCEs[*pceName]
+		if !ok {
+			logger.Error("PCE not found in configuration", "pce", *pceName)
+			os.Exit(1)
+		}
+		if err := restoreFirewallSettings(pceInfo, *restorePath, *insecure); err != nil {
+			logger.Error("error restoring firewall settings", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Batch mode: reconcile every PCE listed in the manifest and exit.
+	if *manifestPath != "" {
+		manifest, err := loadManifest(*manifestPath)
+		if err != nil {
+			logger.Error("error loading manifest", "error", err)
+			os.Exit(1)
+		}
+
+		if runManifestBatch(config, manifest, *insecure, *dryRun, *outputFormat, *backupDir) {
+			os.Exit(1)
+		}
+		return
 	}
 
 	// If pceName is not provided, use the default
@@ -130,30 +152,48 @@ func main() {
 		}
 	}
 
+	report := ReconcileReport{PCE: *pceName, DryRun: *dryRun}
+	client := newPCEClient(pceInfo, *insecure)
+
 	// Step 1: Check and enable report in PCE (First API)
-	err = checkAndEnableReport(pceInfo, *insecure)
+	tmpl, err := client.GetReportTemplate()
 	if err != nil {
-		log.Fatalf("Error enabling report: %v", err)
+		logger.Error("error fetching report status", "error", err)
+		os.Exit(1)
+	}
+	report.ReportAlreadyEnabled = tmpl.Enabled
+
+	if !tmpl.Enabled && !*dryRun {
+		if err := checkAndEnableReport(pceInfo, *insecure); err != nil {
+			logger.Error("error enabling report", "error", err)
+			os.Exit(1)
+		}
 	}
 
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter label to define the scope (or 'All' to enable for all scopes, 'disable' to disable):")
+	fmt.Print("Enter label to define the scope (or 'All' to enable for all scopes, 'disable' to disable); qualify ambiguous values as key:value, e.g. env:prod: ")
 	labelInput, _ := reader.ReadString('\n')
 	labelInput = strings.TrimSpace(labelInput)
 
 	// If the user enters "disable", handle accordingly
-	var payload string
+	var desiredScopes [][]interface{}
 	if strings.EqualFold(labelInput, "disable") {
-		payload = `{"rule_hit_count_enabled_scopes":[]}`
-		fmt.Println("Disabling rule hit count for all scopes...")
+		desiredScopes = [][]interface{}{}
 	} else if strings.EqualFold(labelInput, "all") {
-		payload = `{"rule_hit_count_enabled_scopes":[[]]}`
-		fmt.Println("Enabling rule hit count for all scopes...")
+		desiredScopes = [][]interface{}{{}}
 	} else {
+		// Fetch every label once up front so resolving several labels in the
+		// loop below doesn't re-fetch /labels each time.
+		resolver, err := newLabelResolver(client)
+		if err != nil {
+			logger.Error("failed to fetch labels", "error", err)
+			os.Exit(1)
+		}
+
 		// Handle label input
-		var scopes []map[string]map[string]string
+		var scopeLabels []interface{}
 		for {
-			labelHref, err := checkLabelHref(pceInfo, labelInput, *insecure)
+			labelHref, err := resolver.ResolveReference(labelInput)
 			if err != nil {
 				fmt.Println(err)
 				fmt.Print("Please enter a valid label:")
@@ -162,8 +202,8 @@ func main() {
 				continue
 			}
 
-			scopes = append(scopes, map[string]map[string]string{
-				"label": {
+			scopeLabels = append(scopeLabels, map[string]interface{}{
+				"label": map[string]interface{}{
 					"href": labelHref,
 				},
 			})
@@ -179,61 +219,48 @@ func main() {
 			labelInput = strings.TrimSpace(labelInput)
 		}
 
-		// Create payload with the specified labels
-		payloadBytes, _ := json.Marshal(map[string][][]map[string]map[string]string{
-			"rule_hit_count_enabled_scopes": {scopes},
-		})
-		payload = string(payloadBytes)
+		desiredScopes = [][]interface{}{scopeLabels}
 	}
 
-	// Update the API calls to use pceInfo
-	url := fmt.Sprintf("https://%s:%s/api/v2/orgs/%s/sec_policy/draft/firewall_settings", pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID)
-
 	// First, check the current settings to see if the action is necessary
-	fmt.Println("Checking current firewall settings to see if the rule hit count is already enabled...")
-	statusCode, currentSettingsBody, err := pceutils.MakeAPICall(url, "GET", pceInfo.APIKey, pceInfo.APISecret, "", *insecure)
-	if err != nil || statusCode < 200 || statusCode >= 300 {
-		log.Fatalf("Failed to fetch current firewall settings, HTTP Code: %d, Error: %v", statusCode, err)
-	}
-
-	// Parse current firewall settings
-	var currentSettings FirewallSettings
-	err = json.Unmarshal(currentSettingsBody, &currentSettings)
+	currentSettings, currentSettingsRaw, err := client.GetDraftFirewallSettings()
 	if err != nil {
-		log.Fatalf("Failed to parse current firewall settings: %v", err)
+		logger.Error("failed to fetch current firewall settings", "error", err)
+		os.Exit(1)
 	}
 
-	// Compare current rule_hit_count_enabled_scopes with the new payload to decide whether to skip the API call
-	var newPayloadSettings FirewallSettings
-	err = json.Unmarshal([]byte(payload), &newPayloadSettings)
-	if err != nil {
-		log.Fatalf("Failed to parse new payload settings: %v", err)
+	report.CurrentScopes = scopesToStrings(currentSettings.RuleHitCountEnabledScopes)
+	report.DesiredScopes = scopesToStrings(desiredScopes)
+	report.AddedLabels, report.RemovedLabels = diffHrefs(currentSettings.RuleHitCountEnabledScopes, desiredScopes)
+
+	// Compare the current settings with the desired scopes
+	if scopes.Equal(currentSettings.RuleHitCountEnabledScopes, desiredScopes) {
+		report.Status = "skipped"
+		printReport(report, *outputFormat)
+		return
 	}
 
-	// Compare the current settings with the new payload
-	if len(currentSettings.RuleHitCountEnabledScopes) == len(newPayloadSettings.RuleHitCountEnabledScopes) {
-		equal := true
-		for i := range currentSettings.RuleHitCountEnabledScopes {
-			if len(currentSettings.RuleHitCountEnabledScopes[i]) != len(newPayloadSettings.RuleHitCountEnabledScopes[i]) {
-				equal = false
-				break
-			}
-		}
+	report.Changed = true
 
-		if equal {
-			fmt.Println("Rule hit count is already configured as desired. No changes necessary.")
-			return
-		}
+	if *dryRun {
+		report.Status = "success"
+		printReport(report, *outputFormat)
+		return
 	}
 
-	// If not equal, proceed with making the API call to enable rule hit count
-	fmt.Println("Enabling rule hit count based on the new scope configuration...")
-	statusCode, response, err := pceutils.MakeAPICall(url, "PUT", pceInfo.APIKey, pceInfo.APISecret, payload, *insecure)
-	if err != nil || statusCode < 200 || statusCode >= 300 {
-		log.Fatalf("Failed to enable rule hit count, HTTP Code: %d, Error: %v", statusCode, err)
+	// Snapshot the current settings so a bad roll-out can be undone with -restore.
+	backupPath, err := backupFirewallSettings(*backupDir, *pceName, currentSettingsRaw)
+	if err != nil {
+		logger.Error("error backing up firewall settings", "error", err)
+		os.Exit(1)
 	}
+	logger.Info("backed up current firewall settings", "path", backupPath)
 
-	fmt.Printf("HTTP Code: %d\nResponse: %s\n", statusCode, string(response))
+	// If not equal, proceed with making the API call to enable rule hit count
+	if err := client.UpdateDraftFirewallSettings(scopes.FirewallSettings{RuleHitCountEnabledScopes: desiredScopes}); err != nil {
+		logger.Error("failed to enable rule hit count", "error", err)
+		os.Exit(1)
+	}
 
 	// Optionally, you can also handle provisioning here if needed (depends on your existing logic)
 	// For example, ask for confirmation before making a provisioning API call
@@ -241,20 +268,13 @@ func main() {
 	confirmation, _ := reader.ReadString('\n')
 	confirmation = strings.TrimSpace(confirmation)
 	if strings.ToLower(confirmation) == "y" || confirmation == "" {
-		fmt.Println("Provisioning changes...")
-		provisionURL := fmt.Sprintf("https://%s:%s/api/v2/orgs/%s/sec_policy", pceInfo.FQDN, pceInfo.Port, pceInfo.OrgID)
-		provisionPayload := fmt.Sprintf(`{
-            "update_description":"Enable rule hit count",
-            "change_subset":{"firewall_settings":[{"href":"/orgs/%s/sec_policy/draft/firewall_settings"}]}
-        }`, pceInfo.OrgID)
-
-		statusCode, provisionResponse, err := pceutils.MakeAPICall(provisionURL, "POST", pceInfo.APIKey, pceInfo.APISecret, provisionPayload, *insecure)
-		if err != nil || statusCode < 200 || statusCode >= 300 {
-			log.Fatalf("Failed to provision changes, HTTP Code: %d, Error: %v", statusCode, err)
+		if err := provisionChanges(pceInfo, "Enable rule hit count", *insecure); err != nil {
+			logger.Error("error provisioning changes", "error", err)
+			os.Exit(1)
 		}
-
-		fmt.Printf("HTTP Code: %d\nProvisioning Response: %s\n", statusCode, string(provisionResponse))
-	} else {
-		fmt.Println("Provisioning skipped.")
+		report.WouldProvision = true
 	}
+
+	report.Status = "success"
+	printReport(report, *outputFormat)
 }
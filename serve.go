@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/csmanutd/Rule-hit-counter-auto-enablement/internal/metrics"
+	"github.com/csmanutd/pceutils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// runServe implements the `serve` subcommand: it re-reads the manifest every
+// -interval, reconciles each PCE, and exposes the results as Prometheus
+// metrics on -metrics-addr until the process is killed.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	manifestPath := fs.String("manifest", "", "Path to the YAML manifest to reconcile on every interval")
+	interval := fs.Duration("interval", 15*time.Minute, "How often to re-read the manifest and reconcile")
+	insecure := fs.Bool("insecure", false, "Ignore SSL certificate errors")
+	backupDir := fs.String("backup-dir", "./backups", "Directory to snapshot firewall_settings into before mutating them")
+	metricsAddr := fs.String("metrics-addr", ":9090", "Address to serve /metrics on")
+	logLevel := fs.String("log-level", "info", "Log level: debug, info, warn, or error")
+	logFormat := fs.String("log-format", "text", "Log format: text or json")
+	fs.Parse(args)
+	setupLogger(*logLevel, *logFormat)
+
+	if *manifestPath == "" {
+		logger.Error("serve: -manifest is required")
+		os.Exit(1)
+	}
+
+	config, err := pceutils.LoadOrCreatePCEConfig("pce.json")
+	if err != nil {
+		logger.Error("serve: error loading or creating config", "error", err)
+		os.Exit(1)
+	}
+
+	collectors := metrics.New()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(collectors.Registry, promhttp.HandlerOpts{}))
+	go func() {
+		logger.Info("serve: listening", "addr", *metricsAddr)
+		if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+			logger.Error("serve: metrics server failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	reconcileAll := func() {
+		manifest, err := loadManifest(*manifestPath)
+		if err != nil {
+			logger.Error("serve: failed to load manifest", "error", err)
+			return
+		}
+
+		for _, entry := range manifest.PCEs {
+			pceInfo, ok := config.PCEs[entry.Name]
+			if !ok {
+				logger.Error("serve: PCE not found in configuration", "pce", entry.Name)
+				collectors.ReconcileTotal.WithLabelValues(entry.Name, "failed").Inc()
+				collectors.APIErrorsTotal.WithLabelValues(entry.Name, "config").Inc()
+				continue
+			}
+
+			start := time.Now()
+			report := reconcilePCE(pceInfo, entry, *insecure, false, *backupDir)
+			collectors.ReconcileDuration.WithLabelValues(entry.Name).Observe(time.Since(start).Seconds())
+			collectors.ReconcileTotal.WithLabelValues(entry.Name, report.Status).Inc()
+
+			if report.Status == "failed" {
+				logger.Error("serve: reconcile failed", "pce", entry.Name, "error", report.Error)
+				collectors.APIErrorsTotal.WithLabelValues(entry.Name, report.FailedEndpoint).Inc()
+				continue
+			}
+
+			logger.Info("serve: reconciled", "pce", entry.Name, "status", report.Status)
+			collectors.ReportEnabled.WithLabelValues(entry.Name).Set(boolToFloat(report.ReportAlreadyEnabled))
+			collectors.ScopesConfigured.WithLabelValues(entry.Name).Set(float64(len(entry.Scopes)))
+		}
+	}
+
+	reconcileAll()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		reconcileAll()
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}